@@ -1,24 +1,52 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"time"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/coralogix/go-coralogix-sdk"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/api/iterator"
+
+	"github.com/SebastianPereiro/secrets-cleaner/internal/cleaner"
+	applog "github.com/SebastianPereiro/secrets-cleaner/internal/log"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/metrics"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/notify"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/policy"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store/aws"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store/azure"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store/gsm"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store/vault"
 )
 
 var (
 	// Command line flags
-	project string
-	dryrun  bool
-	debug   bool
+	project       string
+	projects      string
+	projectsFile  string
+	schedule      string
+	daemon        bool
+	dryrun        bool
+	debug         bool
+	metricsAddr   string
+	backend       string
+	vaultAddr     string
+	vaultToken    string
+	azureVaultURL string
+	policyFile    string
+	concurrency   int
+	reportFile    string
+	notifyWebhook string
+	failOnDestroy int
 	// Coralogix creds
 	coralogix_app_name       string = os.Getenv("CORALOGIX_APP_NAME")
 	coralogix_key_gsm_name   string = os.Getenv("CORALOGIX_KEY_GSM_NAME")
@@ -29,209 +57,153 @@ var (
 
 func init() {
 	flag.StringVar(&project, "project", "", "Google Cloud Project")
+	flag.StringVar(&projects, "projects", "", "Comma-separated list of Google Cloud Projects to clean up")
+	flag.StringVar(&projectsFile, "projects-file", "", "Path to a file with one Google Cloud Project per line")
+	flag.StringVar(&schedule, "schedule", "", "Cron expression (e.g. \"0 3 * * *\") to run the cleanup pass on, used with --daemon")
+	flag.BoolVar(&daemon, "daemon", false, "Keep the process running and clean up on the --schedule instead of exiting after one pass")
 	flag.BoolVar(&dryrun, "dry-run", false, "Just analyze the Secrets and propose the changes")
 	flag.BoolVar(&debug, "debug", false, "Add additional debugging output")
 	flag.IntVar(&keepVersions, "keepversions", 2, "Disabled versions to keep")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on (e.g. \":9090\"), disabled if empty")
+	flag.StringVar(&backend, "backend", "gsm", "Secrets backend to clean up: gsm, aws, vault or azure")
+	flag.StringVar(&vaultAddr, "vault-addr", "", "Vault address, required for --backend vault")
+	flag.StringVar(&vaultToken, "vault-token", os.Getenv("VAULT_TOKEN"), "Vault token, required for --backend vault")
+	flag.StringVar(&azureVaultURL, "azure-vault-url", "", "Azure Key Vault URL, required for --backend azure")
+	flag.StringVar(&policyFile, "policy", "", "Path to a retention policy YAML file, overrides --keepversions per secret")
+	flag.IntVar(&concurrency, "concurrency", 5, "Number of secrets to clean up in parallel per project")
+	flag.StringVar(&reportFile, "report-file", "", "Write the --dry-run diff report to this path, as <path>.json and <path>.md")
+	flag.StringVar(&notifyWebhook, "notify-webhook", "", "Slack-compatible incoming webhook URL to POST a run summary to")
+	flag.IntVar(&failOnDestroy, "fail-on-destroy-count", -1, "Exit non-zero if more than this many versions are destroyed in one pass, disabled if negative; not checked with --daemon")
 }
 
-type SecretName struct {
-	Name string
-}
-
-type SecretVersion struct {
-	Name              string
-	CreateTimeSeconds int64
-	CreateTimeNanos   int32
-}
-
-// Get all secrets for a given project
-func listSecrets(ctx context.Context, c *secretmanager.Client, projectName string) ([]SecretName, error) {
-	req := &secretmanagerpb.ListSecretsRequest{
-		Parent: projectName,
-	}
-	var secrets []SecretName
-	it := c.ListSecrets(ctx, req)
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
+// newSecretStore builds the store.SecretStore selected by --backend.
+func newSecretStore(ctx context.Context) (store.SecretStore, error) {
+	switch backend {
+	case "gsm":
+		return gsm.New(ctx)
+	case "aws":
+		return aws.New(ctx)
+	case "vault":
+		if vaultAddr == "" {
+			return nil, fmt.Errorf("--vault-addr is required for --backend vault")
 		}
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"timestamp":     time.Now(),
-				"unixtimestamp": time.Now().UnixNano(),
-				"project":       project,
-			}).Fatalf("Failed to get the list of secrets: %v", err)
+		return vault.New(vaultAddr, vaultToken)
+	case "azure":
+		if azureVaultURL == "" {
+			return nil, fmt.Errorf("--azure-vault-url is required for --backend azure")
 		}
-		secrets = append(secrets, SecretName{Name: resp.Name})
+		return azure.New(azureVaultURL)
+	default:
+		return nil, fmt.Errorf("unknown --backend %q", backend)
 	}
-	return secrets, nil
 }
 
-// Disable all enabled secret versions except the latest one for a given secret
-func disableExceptThelatestVersions(ctx context.Context, c *secretmanager.Client, secretName string) {
-	// get all secret versions and store them in slice
-	req := &secretmanagerpb.ListSecretVersionsRequest{
-		Parent: secretName,
-		Filter: "state:ENABLED", // https://cloud.google.com/secret-manager/docs/filtering
+// Show usage options if no project specified
+func customHelp() {
+	fmt.Println("Usage: cleaner [OPTIONS]")
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+	fmt.Println()
+}
+
+// resolveProjects builds the final project list from --project, --projects
+// and --projects-file combined.
+func resolveProjects() ([]string, error) {
+	var result []string
+	if project != "" {
+		result = append(result, project)
 	}
-	var versions []SecretVersion
-	it := c.ListSecretVersions(ctx, req)
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
+	if projects != "" {
+		for _, p := range strings.Split(projects, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				result = append(result, p)
+			}
 		}
+	}
+	if projectsFile != "" {
+		f, err := os.Open(projectsFile)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"timestamp":     time.Now(),
-				"unixtimestamp": time.Now().UnixNano(),
-				"project":       project,
-			}).Fatalf("Failed to get secret versions: %v", err)
+			return nil, fmt.Errorf("failed to open projects file: %w", err)
 		}
-		// versions slice contains the list of secret versions sorted in reverse by create_time (newest first).
-		// https://pkg.go.dev/google.golang.org/genproto/googleapis/cloud/secretmanager/v1beta1#ListSecretVersionsResponse
-		versions = append(versions, SecretVersion{Name: resp.Name, CreateTimeSeconds: resp.CreateTime.Seconds, CreateTimeNanos: resp.CreateTime.Nanos})
-	}
-	// Show the latest enabled secret version in the debug output
-	logrus.WithFields(logrus.Fields{
-		"timestamp":     time.Now(),
-		"unixtimestamp": time.Now().UnixNano(),
-		"project":       project,
-	}).Debug("The latest enabled secret is : ", versions[0].Name)
-	// In case the versions slice contains more than 1 enabled version
-	if (len(versions) - 1) > 0 {
-		// Iterate throught all elements except the first (latest version) one
-		for _, version := range versions[1:] {
-			if dryrun {
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Debug("Secrect version to disable: ", version.Name)
-			} else {
-				// Request to disable
-				req := &secretmanagerpb.DisableSecretVersionRequest{
-					Name: version.Name,
-				}
-				resp, err := c.DisableSecretVersion(ctx, req)
-				if err != nil {
-					logrus.WithFields(logrus.Fields{
-						"timestamp":     time.Now(),
-						"unixtimestamp": time.Now().UnixNano(),
-						"project":       project,
-					}).Fatalf("Failed to disable secret version: %v", err)
-				}
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Info("Disabled the secret version: ", version.Name)
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Debug("Operation responce", resp)
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if p := strings.TrimSpace(scanner.Text()); p != "" {
+				result = append(result, p)
 			}
 		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read projects file: %w", err)
+		}
 	}
+	return result, nil
 }
 
-// Destroy disabled versions for a given secret
-func destroyDisabledVersions(ctx context.Context, c *secretmanager.Client, secretName string) {
-	// search only for disabled versions
-	req := &secretmanagerpb.ListSecretVersionsRequest{
-		Parent: secretName,
-		Filter: "state:DISABLED", // https://cloud.google.com/secret-manager/docs/filtering
-	}
-	var versionsDisabled []SecretVersion
-	it := c.ListSecretVersions(ctx, req)
-	for {
-		resp, err := it.Next()
-		if err == iterator.Done {
-			break
+// handleSummary persists the dry-run report, if any, to --report-file and
+// posts a summary to --notify-webhook. Failures here are logged, not fatal:
+// a broken webhook or an unwritable report path shouldn't fail the run.
+func handleSummary(ctx context.Context, logger *applog.Logger, projectList []string, summary cleaner.Summary) {
+	if reportFile != "" && summary.Report != nil {
+		entries := summary.Report.Entries()
+		if data, err := summary.Report.JSONFrom(entries); err != nil {
+			logger.Errorf("Failed to render JSON report: %v", err)
+		} else if err := os.WriteFile(reportFile+".json", data, 0o644); err != nil {
+			logger.Errorf("Failed to write JSON report: %v", err)
 		}
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"timestamp":     time.Now(),
-				"unixtimestamp": time.Now().UnixNano(),
-				"project":       project,
-			}).Fatalf("Failed to get secret versions: %v", err)
+		if err := os.WriteFile(reportFile+".md", []byte(summary.Report.MarkdownFrom(entries)), 0o644); err != nil {
+			logger.Errorf("Failed to write Markdown report: %v", err)
 		}
-		versionsDisabled = append(versionsDisabled, SecretVersion{Name: resp.Name, CreateTimeSeconds: resp.CreateTime.Seconds, CreateTimeNanos: resp.CreateTime.Nanos})
 	}
-	// If we have more disabled versions than keepVersions
-	if len(versionsDisabled) > keepVersions {
-		for _, version := range versionsDisabled[keepVersions:] {
-			if dryrun {
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Debug("Secret version to destroy: ", version.Name)
-			} else {
-				destroyReq := &secretmanagerpb.DestroySecretVersionRequest{
-					Name: version.Name,
-				}
-				destroyResp, err := c.DestroySecretVersion(ctx, destroyReq)
-				if err != nil {
-					logrus.WithFields(logrus.Fields{
-						"timestamp":     time.Now(),
-						"unixtimestamp": time.Now().UnixNano(),
-						"project":       project,
-					}).Fatalf("Failed to destroy secret version: %v", err)
-				}
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Info("Destroyed the secret version: ", version.Name)
-				logrus.WithFields(logrus.Fields{
-					"timestamp":     time.Now(),
-					"unixtimestamp": time.Now().UnixNano(),
-					"project":       project,
-				}).Debug("Destroy operation responce", destroyResp)
-			}
+
+	if notifyWebhook != "" {
+		if err := notify.PostSummary(ctx, notifyWebhook, projectList, backend, dryrun, summary); err != nil {
+			logger.Errorf("Failed to post webhook summary: %v", err)
 		}
 	}
 }
 
-// Show usage options if no project specified
-func customHelp() {
-	fmt.Println("Usage: cleaner [OPTIONS]")
-	fmt.Println("Options:")
-	flag.PrintDefaults()
-	fmt.Println()
-}
-
 func main() {
 	// Check the cmd line args
 	flag.Parse()
-	if project == "" {
+
+	logger := applog.New()
+
+	projectList, err := resolveProjects()
+	if err != nil {
+		logger.Fatalf("Failed to resolve projects: %v", err)
+	}
+	if len(projectList) == 0 {
+		customHelp()
+		os.Exit(1)
+	}
+	if daemon && schedule == "" {
 		customHelp()
 		os.Exit(1)
 	}
 
 	// Debug
 	if debug {
-		logrus.SetLevel(logrus.DebugLevel)
+		logger.SetLevel(logrus.DebugLevel)
 		coralogix.SetDebug(true)
 	}
 
-	// The main context
-	ctx := context.Background()
-	c, err := secretmanager.NewClient(ctx)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"timestamp":     time.Now(),
-			"unixtimestamp": time.Now().UnixNano(),
-			"project":       project,
-		}).Fatalf("Failed to create Secret Manager client: %v", err)
+	if metricsAddr != "" {
+		metrics.Serve(metricsAddr, logger)
 	}
-	defer c.Close()
+
+	// The main context, cancelled on SIGINT/SIGTERM so any in-flight
+	// DisableSecretVersion/DestroySecretVersion calls get to finish cleanly
+	// before the process exits.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// If we have CORALOGIX_KEY_GSM_NAME and CORALOGIX_APP_NAME env variables defined, enable Coralogix logging
 	if coralogix_key_gsm_name != "" && coralogix_app_name != "" {
+		c, err := secretmanager.NewClient(ctx)
+		if err != nil {
+			logger.Fatalf("Failed to create Secret Manager client: %v", err)
+		}
+
 		// Get Coralogix credentials from the secret name obtained from ENV
 		// Access the secret from Secret Manager.
 		accessRequest := &secretmanagerpb.AccessSecretVersionRequest{
@@ -240,12 +212,9 @@ func main() {
 
 		coralogix_private_key, err := c.AccessSecretVersion(ctx, accessRequest)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"timestamp":     time.Now(),
-				"unixtimestamp": time.Now().UnixNano(),
-				"project":       project,
-			}).Fatalf("Failed to access secret version: %v", err)
+			logger.Fatalf("Failed to access secret version: %v", err)
 		}
+		c.Close()
 
 		// Initialise logging to Coralogix
 		// Coralogix docs:
@@ -256,35 +225,59 @@ func main() {
 			coralogix_app_name,
 			coralogix_subsystem_name,
 		)
-		logrus.AddHook(CoralogixHook)
+		logger.AddHook(CoralogixHook)
 		defer CoralogixHook.Close()
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"timestamp":     time.Now(),
-		"unixtimestamp": time.Now().UnixNano(),
-		"project":       project,
-	}).Info("Starting the secrets cleaner for ", project, " project")
-
-	// Get all project secrets
-	secrets, err := listSecrets(ctx, c, "projects/"+project)
+	secretStore, err := newSecretStore(ctx)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"timestamp":     time.Now(),
-			"unixtimestamp": time.Now().UnixNano(),
-			"project":       project,
-		}).Fatalf("Failed to list secrets: %v", err)
+		logger.Fatalf("Failed to create %s secret store: %v", backend, err)
+	}
+	if closer, ok := secretStore.(io.Closer); ok {
+		defer closer.Close()
 	}
 
-	for _, secret := range secrets {
-		disableExceptThelatestVersions(ctx, c, secret.Name)
-		destroyDisabledVersions(ctx, c, secret.Name)
+	var retentionPolicy *policy.Policy
+	if policyFile != "" {
+		retentionPolicy, err = policy.Load(policyFile)
+		if err != nil {
+			logger.Fatalf("Failed to load policy file: %v", err)
+		}
+	}
+
+	runner := cleaner.NewRunner(cleaner.Config{
+		Projects:     projectList,
+		DryRun:       dryrun,
+		Debug:        debug,
+		KeepVersions: keepVersions,
+		Policy:       retentionPolicy,
+		Concurrency:  concurrency,
+		OnComplete: func(summary cleaner.Summary) {
+			handleSummary(ctx, logger, projectList, summary)
+		},
+	}, logger, secretStore)
+
+	if daemon {
+		if err := runner.RunDaemon(ctx, schedule); err != nil {
+			logger.Fatalf("Daemon exited with error: %v", err)
+		}
+		return
 	}
 
-	// Exit the app
-	logrus.WithFields(logrus.Fields{
-		"timestamp":     time.Now(),
-		"unixtimestamp": time.Now().UnixNano(),
-		"project":       project,
-	}).Info("Stopping the secrets cleaner")
+	summary, err := runner.RunOnce(ctx)
+	if err != nil {
+		logger.Fatalf("Cleanup pass aborted: %v", err)
+	}
+	logger.Infof("Cleanup summary: scanned=%d disabled=%d destroyed=%d failed=%d",
+		summary.SecretsScanned, summary.VersionsDisabled, summary.VersionsDestroyed, len(summary.Failures))
+	if summary.Failed() {
+		for _, f := range summary.Failures {
+			logger.Errorf("Secret %s in project %s failed: %v", f.Secret, f.Project, f.Err)
+		}
+		os.Exit(1)
+	}
+	if failOnDestroy >= 0 && summary.VersionsDestroyed > failOnDestroy {
+		logger.Errorf("Destroyed %d versions, exceeding --fail-on-destroy-count %d", summary.VersionsDestroyed, failOnDestroy)
+		os.Exit(1)
+	}
 }
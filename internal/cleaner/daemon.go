@@ -0,0 +1,43 @@
+package cleaner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// RunDaemon keeps the process alive, running a cleanup pass every time the
+// given cron expression fires, until ctx is cancelled. Once ctx is cancelled
+// (e.g. on SIGINT/SIGTERM), RunDaemon waits for any in-flight pass to finish
+// before returning, so DisableSecretVersion/DestroySecretVersion calls are
+// never interrupted mid-flight.
+func (r *Runner) RunDaemon(ctx context.Context, schedule string) error {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	sched, err := parser.Parse(schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+
+	r.log.Info("Starting the secrets cleaner daemon on schedule: ", schedule)
+
+	next := sched.Next(time.Now())
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.log.Info("Shutdown requested, stopping the secrets cleaner daemon")
+			return nil
+		case now := <-timer.C:
+			summary, err := r.RunOnce(ctx)
+			if err != nil {
+				r.log.Errorf("Scheduled cleanup pass aborted: %v", err)
+			} else if summary.Failed() {
+				r.log.Errorf("Scheduled cleanup pass finished with %d failed secrets", len(summary.Failures))
+			}
+			next = sched.Next(now)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package cleaner implements the core secret-version retention pass, shared
+// between the one-shot CLI invocation and the long-running daemon mode. It
+// is backend-agnostic: it drives cleanup through the store.SecretStore
+// interface rather than talking to any one secrets backend directly.
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/SebastianPereiro/secrets-cleaner/internal/log"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/metrics"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/policy"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/report"
+	"github.com/SebastianPereiro/secrets-cleaner/internal/retry"
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+// Config holds everything a Runner needs to perform cleanup passes.
+type Config struct {
+	// Projects is the list of backend scopes (e.g. GCP projects) to clean up
+	// on each pass.
+	Projects []string
+	// DryRun, when true, only logs what would be disabled/destroyed.
+	DryRun bool
+	// Debug enables verbose logging.
+	Debug bool
+	// KeepVersions is the default number of disabled versions to keep per
+	// secret, used when no policy rule matches.
+	KeepVersions int
+	// Policy, if set, overrides KeepVersions per secret based on labels, and
+	// adds age thresholds and pinned versions. May be nil.
+	Policy *policy.Policy
+	// Concurrency is how many secrets to clean up in parallel per project.
+	Concurrency int
+	// OnComplete, if set, is called with the Summary of every completed
+	// pass, one-shot or scheduled, before RunOnce returns. Used to persist
+	// dry-run reports and send notifications without coupling this package
+	// to either concern.
+	OnComplete func(Summary)
+}
+
+// Runner drives cleanup passes against a store.SecretStore, either once or
+// repeatedly on a schedule.
+type Runner struct {
+	store store.SecretStore
+	cfg   Config
+	log   *log.Logger
+}
+
+// NewRunner creates a Runner that cleans up secrets through the given store.
+func NewRunner(cfg Config, logger *log.Logger, secretStore store.SecretStore) *Runner {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	return &Runner{store: secretStore, cfg: cfg, log: logger}
+}
+
+// SecretFailure records a secret whose cleanup failed even after retries.
+type SecretFailure struct {
+	Project string
+	Secret  string
+	Err     error
+}
+
+// SecretStat is the per-secret version churn from one cleanup pass, used to
+// rank the noisiest secrets in a notification.
+type SecretStat struct {
+	Project   string
+	Secret    string
+	Disabled  int
+	Destroyed int
+}
+
+// Churn is the total number of versions disabled and destroyed.
+func (s SecretStat) Churn() int {
+	return s.Disabled + s.Destroyed
+}
+
+// Summary reports the outcome of one or more cleanup passes. In --dry-run
+// mode, the counts reflect what would have happened, except that
+// VersionsDestroyed (and Report) also include versions on backends whose
+// DestroyVersion returns store.ErrDestroyUnsupported, which a live pass
+// leaves in place.
+type Summary struct {
+	SecretsScanned    int
+	VersionsDisabled  int
+	VersionsDestroyed int
+	Failures          []SecretFailure
+	SecretStats       []SecretStat
+	// Report is the detailed per-version dry-run diff, set only in
+	// --dry-run mode.
+	Report *report.Report
+}
+
+// Failed reports whether any secret in the summary failed to clean up.
+func (s Summary) Failed() bool {
+	return len(s.Failures) > 0
+}
+
+func (s *Summary) merge(other Summary) {
+	s.SecretsScanned += other.SecretsScanned
+	s.VersionsDisabled += other.VersionsDisabled
+	s.VersionsDestroyed += other.VersionsDestroyed
+	s.Failures = append(s.Failures, other.Failures...)
+	s.SecretStats = append(s.SecretStats, other.SecretStats...)
+}
+
+// RunOnce performs a single cleanup pass across every configured project. A
+// secret whose cleanup fails, even after retries, is recorded in the
+// returned Summary rather than aborting the run.
+//
+// ctx being cancelled (e.g. on SIGINT/SIGTERM) stops RunOnce from starting
+// work on any further project, but a project already being cleaned up is
+// given a context detached from ctx's cancellation, so its in-flight
+// DisableVersion/DestroyVersion calls get to finish cleanly rather than
+// aborting mid-flight with context.Canceled.
+func (r *Runner) RunOnce(ctx context.Context) (Summary, error) {
+	summary := Summary{}
+	if r.cfg.DryRun {
+		summary.Report = report.New(time.Now())
+	}
+	workCtx := context.WithoutCancel(ctx)
+	var aborted error
+	for _, project := range r.cfg.Projects {
+		if err := ctx.Err(); err != nil {
+			aborted = err
+			break
+		}
+		projectSummary, err := r.runProject(workCtx, project, summary.Report)
+		summary.merge(projectSummary)
+		if err != nil {
+			r.log.SetProject(project)
+			r.log.Errorf("Cleanup pass failed for project: %v", err)
+		}
+	}
+	metrics.LastRunTimestamp.Set(float64(time.Now().Unix()))
+	if r.cfg.OnComplete != nil {
+		r.cfg.OnComplete(summary)
+	}
+	return summary, aborted
+}
+
+func (r *Runner) runProject(ctx context.Context, project string, rpt *report.Report) (Summary, error) {
+	r.log.SetProject(project)
+	r.log.Info("Starting the secrets cleaner for ", project, " project")
+
+	secrets, err := r.store.ListSecrets(ctx, project)
+	if err != nil {
+		metrics.GSMAPIErrors.WithLabelValues("list_secrets").Inc()
+		return Summary{}, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	metrics.SecretsScanned.Add(float64(len(secrets)))
+
+	summary := Summary{SecretsScanned: len(secrets)}
+	var mu sync.Mutex
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(r.cfg.Concurrency)
+	for _, secret := range secrets {
+		secret := secret
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			disabled, destroyed, err := r.cleanupSecret(gctx, project, secret, rpt)
+			mu.Lock()
+			defer mu.Unlock()
+			summary.VersionsDisabled += disabled
+			summary.VersionsDestroyed += destroyed
+			if disabled > 0 || destroyed > 0 {
+				summary.SecretStats = append(summary.SecretStats, SecretStat{
+					Project: project, Secret: secret.Name, Disabled: disabled, Destroyed: destroyed,
+				})
+			}
+			if err != nil {
+				r.log.Errorf("Failed to clean up secret %s: %v", secret.Name, err)
+				summary.Failures = append(summary.Failures, SecretFailure{Project: project, Secret: secret.Name, Err: err})
+			}
+			return nil
+		})
+	}
+	// The funcs above return an error only when gctx is already cancelled,
+	// so g.Wait only surfaces ctx cancellation.
+	if err := g.Wait(); err != nil {
+		return summary, err
+	}
+
+	r.log.Info("Stopping the secrets cleaner")
+	return summary, nil
+}
+
+// cleanupSecret disables and destroys versions for a single secret,
+// retrying transient backend errors before giving up.
+func (r *Runner) cleanupSecret(ctx context.Context, project string, secret store.Secret, rpt *report.Report) (disabled, destroyed int, err error) {
+	eff := r.cfg.Policy.Resolve(secret, r.cfg.KeepVersions)
+	if eff.Skip {
+		r.log.Debug("Skipping secret per policy: ", secret.Name)
+		return 0, 0, nil
+	}
+	disabled, err = r.disableExceptTheLatestVersions(ctx, project, secret, eff, rpt)
+	if err != nil {
+		return disabled, 0, err
+	}
+	destroyed, err = r.destroyDisabledVersions(ctx, project, secret, eff, rpt)
+	return disabled, destroyed, err
+}
+
+// isRetryable reports whether err is a transient backend error worth
+// retrying. It recognises gRPC status codes only (Unavailable,
+// DeadlineExceeded, ResourceExhausted); errors that carry no gRPC status,
+// such as those from the aws, vault and azure stores, are never retried.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// disableExceptTheLatestVersions disables all enabled secret versions except
+// the newest eff.KeepEnabled of them, skipping pinned versions.
+func (r *Runner) disableExceptTheLatestVersions(ctx context.Context, project string, secret store.Secret, eff policy.Effective, rpt *report.Report) (int, error) {
+	var versions []store.Version
+	err := retry.Do(ctx, retry.DefaultConfig, isRetryable, func() error {
+		var err error
+		versions, err = r.store.ListVersions(ctx, secret, store.VersionStateEnabled)
+		return err
+	})
+	if err != nil {
+		metrics.GSMAPIErrors.WithLabelValues("list_versions").Inc()
+		return 0, fmt.Errorf("failed to get secret versions for %s: %w", secret.Name, err)
+	}
+	if len(versions) <= eff.KeepEnabled {
+		return 0, nil
+	}
+	// versions is sorted in reverse by create_time (newest first).
+	r.log.Debug("The latest enabled secret is : ", versions[0].Name)
+
+	var count int
+	for _, version := range versions[eff.KeepEnabled:] {
+		if eff.Pinned[version.Name] {
+			continue
+		}
+		if r.cfg.DryRun {
+			r.log.Debug("Secrect version to disable: ", version.Name)
+			if rpt != nil {
+				rpt.Add(report.Entry{
+					Project: project, Secret: secret.Name, Version: version.Name,
+					Action: "disable", State: "ENABLED",
+					CreateTime: version.CreateTime, Age: time.Since(version.CreateTime),
+				})
+			}
+			count++
+			continue
+		}
+		err := retry.Do(ctx, retry.DefaultConfig, isRetryable, func() error {
+			return r.store.DisableVersion(ctx, version)
+		})
+		if err != nil {
+			metrics.GSMAPIErrors.WithLabelValues("disable_version").Inc()
+			return count, fmt.Errorf("failed to disable secret version %s: %w", version.Name, err)
+		}
+		metrics.VersionsDisabled.Inc()
+		count++
+		r.log.Info("Disabled the secret version: ", version.Name)
+	}
+	return count, nil
+}
+
+// destroyDisabledVersions destroys disabled versions for a given secret
+// beyond eff.KeepDisabled, skipping pinned versions and any younger than
+// eff.MinAge.
+func (r *Runner) destroyDisabledVersions(ctx context.Context, project string, secret store.Secret, eff policy.Effective, rpt *report.Report) (int, error) {
+	var versionsDisabled []store.Version
+	err := retry.Do(ctx, retry.DefaultConfig, isRetryable, func() error {
+		var err error
+		versionsDisabled, err = r.store.ListVersions(ctx, secret, store.VersionStateDisabled)
+		return err
+	})
+	if err != nil {
+		metrics.GSMAPIErrors.WithLabelValues("list_versions").Inc()
+		return 0, fmt.Errorf("failed to get secret versions for %s: %w", secret.Name, err)
+	}
+	if len(versionsDisabled) <= eff.KeepDisabled {
+		return 0, nil
+	}
+
+	var count int
+	for _, version := range versionsDisabled[eff.KeepDisabled:] {
+		if eff.Pinned[version.Name] {
+			continue
+		}
+		if eff.MinAge > 0 && time.Since(version.CreateTime) < eff.MinAge {
+			continue
+		}
+		if r.cfg.DryRun {
+			r.log.Debug("Secret version to destroy: ", version.Name)
+			if rpt != nil {
+				rpt.Add(report.Entry{
+					Project: project, Secret: secret.Name, Version: version.Name,
+					Action: "destroy", State: "DISABLED",
+					CreateTime: version.CreateTime, Age: time.Since(version.CreateTime),
+				})
+			}
+			count++
+			continue
+		}
+		err := retry.Do(ctx, retry.DefaultConfig, isRetryable, func() error {
+			return r.store.DestroyVersion(ctx, version)
+		})
+		if errors.Is(err, store.ErrDestroyUnsupported) {
+			// The backend has no way to destroy a single version (AWS
+			// Secrets Manager, Azure Key Vault). That's an inherent
+			// limitation, not a per-secret failure, so stop trying for the
+			// rest of this secret's disabled versions rather than spamming
+			// the same error.
+			r.log.Debug("Backend does not support destroying individual versions, skipping: ", secret.Name)
+			return count, nil
+		}
+		if err != nil {
+			metrics.GSMAPIErrors.WithLabelValues("destroy_version").Inc()
+			return count, fmt.Errorf("failed to destroy secret version %s: %w", version.Name, err)
+		}
+		metrics.VersionsDestroyed.Inc()
+		count++
+		r.log.Info("Destroyed the secret version: ", version.Name)
+	}
+	return count, nil
+}
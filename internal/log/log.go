@@ -0,0 +1,61 @@
+// Package log provides the cleaner's shared logrus configuration so call
+// sites log a plain message instead of repeating the same WithFields block
+// everywhere.
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is a *logrus.Logger that auto-attaches project, timestamp,
+// unixtimestamp and run_id to every entry via a hook.
+type Logger struct {
+	*logrus.Logger
+	hook *contextHook
+}
+
+// New returns a Logger configured with JSON output and a fresh run ID.
+func New() *Logger {
+	l := logrus.New()
+	l.SetFormatter(&logrus.JSONFormatter{})
+	hook := &contextHook{runID: uuid.NewString()}
+	l.AddHook(hook)
+	return &Logger{Logger: l, hook: hook}
+}
+
+// SetProject updates the project attached to every subsequent log entry.
+// Runner calls this before starting each project's cleanup pass.
+func (l *Logger) SetProject(project string) {
+	l.hook.setProject(project)
+}
+
+type contextHook struct {
+	mu      sync.Mutex
+	project string
+	runID   string
+}
+
+func (h *contextHook) setProject(project string) {
+	h.mu.Lock()
+	h.project = project
+	h.mu.Unlock()
+}
+
+func (h *contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *contextHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	project := h.project
+	h.mu.Unlock()
+	entry.Data["project"] = project
+	entry.Data["timestamp"] = time.Now()
+	entry.Data["unixtimestamp"] = time.Now().UnixNano()
+	entry.Data["run_id"] = h.runID
+	return nil
+}
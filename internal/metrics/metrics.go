@@ -0,0 +1,53 @@
+// Package metrics exposes the cleaner's Prometheus metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/SebastianPereiro/secrets-cleaner/internal/log"
+)
+
+var (
+	// SecretsScanned counts secrets seen across all cleanup passes.
+	SecretsScanned = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "secrets_scanned_total",
+		Help: "Total number of secrets scanned by the cleaner.",
+	})
+	// VersionsDisabled counts secret versions disabled across all passes.
+	VersionsDisabled = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "versions_disabled_total",
+		Help: "Total number of secret versions disabled by the cleaner.",
+	})
+	// VersionsDestroyed counts secret versions destroyed across all passes.
+	VersionsDestroyed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "versions_destroyed_total",
+		Help: "Total number of secret versions destroyed by the cleaner.",
+	})
+	// GSMAPIErrors counts Secret Manager API errors, labelled by operation.
+	GSMAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gsm_api_errors_total",
+		Help: "Total number of Secret Manager API errors, by operation.",
+	}, []string{"op"})
+	// LastRunTimestamp is the unix time of the last completed cleanup pass.
+	LastRunTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "last_run_timestamp_seconds",
+		Help: "Unix timestamp of the last completed cleanup pass.",
+	})
+)
+
+// Serve starts an HTTP server exposing /metrics on addr in the background.
+// A bind/serve failure is logged, not fatal: the cleanup pass itself should
+// keep running even if metrics can't be exposed.
+func Serve(addr string, logger *log.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logger.Errorf("Metrics server failed: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+// Package notify posts cleanup-pass summaries to a Slack-compatible
+// incoming webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/SebastianPereiro/secrets-cleaner/internal/cleaner"
+)
+
+// topN is how many secrets by churn to include in the notification.
+const topN = 5
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// PostSummary POSTs a Markdown-formatted summary of summary to webhookURL.
+// projects is the list of scopes cleaned up this run, used for context and,
+// for the "gsm" backend, to link to the GSM console.
+func PostSummary(ctx context.Context, webhookURL string, projects []string, backend string, dryRun bool, summary cleaner.Summary) error {
+	payload := slackMessage{Text: formatSummary(projects, backend, dryRun, summary)}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+func formatSummary(projects []string, backend string, dryRun bool, summary cleaner.Summary) string {
+	mode := "live"
+	if dryRun {
+		mode = "dry-run"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*secrets-cleaner* %s run for `%s`\n", mode, strings.Join(projects, ", "))
+	fmt.Fprintf(&b, "scanned: %d, disabled: %d, destroyed: %d, failed: %d\n",
+		summary.SecretsScanned, summary.VersionsDisabled, summary.VersionsDestroyed, len(summary.Failures))
+
+	if top := topChurn(summary.SecretStats, topN); len(top) > 0 {
+		b.WriteString("Top secrets by churn:\n")
+		for _, stat := range top {
+			fmt.Fprintf(&b, "- %s: %d disabled, %d destroyed\n", stat.Secret, stat.Disabled, stat.Destroyed)
+		}
+	}
+
+	var links []string
+	for _, project := range projects {
+		if link := consoleLink(backend, project); link != "" {
+			links = append(links, link)
+		}
+	}
+	if len(links) > 0 {
+		fmt.Fprintf(&b, "Console: %s\n", strings.Join(links, ", "))
+	}
+
+	return b.String()
+}
+
+func topChurn(stats []cleaner.SecretStat, n int) []cleaner.SecretStat {
+	sorted := make([]cleaner.SecretStat, len(stats))
+	copy(sorted, stats)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Churn() > sorted[j].Churn()
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func consoleLink(backend, project string) string {
+	if backend != "gsm" {
+		return ""
+	}
+	return fmt.Sprintf("https://console.cloud.google.com/security/secret-manager?project=%s", project)
+}
@@ -0,0 +1,152 @@
+// Package policy implements the cleaner's retention policy: per-secret
+// overrides of how many enabled/disabled versions to keep, a minimum age
+// before a disabled version is eligible for destruction, and pinned
+// versions that must never be touched.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+const (
+	// LabelSkip, when set to "true" on a secret, excludes it from cleanup
+	// entirely regardless of any matching rule.
+	LabelSkip = "cleaner/skip"
+	// LabelKeepVersions overrides KeepDisabled for a single secret.
+	LabelKeepVersions = "cleaner/keep-versions"
+)
+
+// Duration unmarshals a YAML string like "168h" into a time.Duration.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// Match selects which secrets a Rule applies to.
+type Match struct {
+	Labels map[string]string `yaml:"labels"`
+}
+
+// Rule is one entry in a Policy's rule list. When omitted (zero),
+// KeepEnabled falls back to 1 and KeepDisabled to the Runner's default,
+// rather than disabling or destroying every version.
+type Rule struct {
+	Match          Match    `yaml:"match"`
+	KeepEnabled    int      `yaml:"keepEnabled"`
+	KeepDisabled   int      `yaml:"keepDisabled"`
+	MinAge         Duration `yaml:"minAge"`
+	PinnedVersions []string `yaml:"pinnedVersions"`
+}
+
+// Policy is a YAML-configured list of retention rules, evaluated in order.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads and parses a policy file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return &p, nil
+}
+
+// Effective is the retention policy resolved for one specific secret.
+type Effective struct {
+	// Skip, when true, excludes the secret from cleanup entirely.
+	Skip bool
+	// KeepEnabled is how many of the newest enabled versions to leave alone.
+	KeepEnabled int
+	// KeepDisabled is how many disabled versions to keep before destroying.
+	KeepDisabled int
+	// MinAge is the minimum age a disabled version must reach before it's
+	// eligible for destruction.
+	MinAge time.Duration
+	// Pinned holds version names that must never be disabled or destroyed.
+	Pinned map[string]bool
+}
+
+// Resolve applies the first matching rule to secret, falling back to
+// defaultKeepDisabled when no rule matches, and then applies per-secret
+// label overrides, which always take precedence over the matched rule.
+func (p *Policy) Resolve(secret store.Secret, defaultKeepDisabled int) Effective {
+	eff := Effective{
+		KeepEnabled:  1,
+		KeepDisabled: defaultKeepDisabled,
+		Pinned:       map[string]bool{},
+	}
+
+	if p != nil {
+		for _, rule := range p.Rules {
+			if !matches(rule.Match.Labels, secret.Labels) {
+				continue
+			}
+			if rule.KeepEnabled > 0 {
+				eff.KeepEnabled = rule.KeepEnabled
+			}
+			if rule.KeepDisabled > 0 {
+				eff.KeepDisabled = rule.KeepDisabled
+			}
+			eff.MinAge = rule.MinAge.Duration
+			for _, v := range rule.PinnedVersions {
+				eff.Pinned[v] = true
+			}
+			break
+		}
+	}
+
+	if secret.Labels[LabelSkip] == "true" {
+		eff.Skip = true
+	}
+	if v := secret.Labels[LabelKeepVersions]; v != "" {
+		if n, err := parseNonNegativeInt(v); err == nil {
+			eff.KeepDisabled = n
+		}
+	}
+
+	return eff
+}
+
+func matches(want, have map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNonNegativeInt(s string) (int, error) {
+	var n int
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative value %q", s)
+	}
+	return n, nil
+}
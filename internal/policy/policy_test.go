@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name                string
+		policy              *Policy
+		secret              store.Secret
+		defaultKeepDisabled int
+		wantSkip            bool
+		wantKeepEnabled     int
+		wantKeepDisabled    int
+		wantMinAge          time.Duration
+	}{
+		{
+			name:                "nil policy falls back to default",
+			policy:              nil,
+			secret:              store.Secret{Name: "s"},
+			defaultKeepDisabled: 3,
+			wantKeepEnabled:     1,
+			wantKeepDisabled:    3,
+		},
+		{
+			name: "no matching rule falls back to default",
+			policy: &Policy{Rules: []Rule{
+				{Match: Match{Labels: map[string]string{"env": "prod"}}, KeepDisabled: 10},
+			}},
+			secret:              store.Secret{Name: "s", Labels: map[string]string{"env": "dev"}},
+			defaultKeepDisabled: 2,
+			wantKeepEnabled:     1,
+			wantKeepDisabled:    2,
+		},
+		{
+			name: "matching rule sets keepEnabled and keepDisabled",
+			policy: &Policy{Rules: []Rule{
+				{Match: Match{Labels: map[string]string{"env": "prod"}}, KeepEnabled: 2, KeepDisabled: 5, MinAge: Duration{2 * time.Hour}},
+			}},
+			secret:              store.Secret{Name: "s", Labels: map[string]string{"env": "prod"}},
+			defaultKeepDisabled: 1,
+			wantKeepEnabled:     2,
+			wantKeepDisabled:    5,
+			wantMinAge:          2 * time.Hour,
+		},
+		{
+			name: "rule setting only keepEnabled keeps the default keepDisabled",
+			policy: &Policy{Rules: []Rule{
+				{Match: Match{Labels: map[string]string{"env": "prod"}}, KeepEnabled: 4},
+			}},
+			secret:              store.Secret{Name: "s", Labels: map[string]string{"env": "prod"}},
+			defaultKeepDisabled: 7,
+			wantKeepEnabled:     4,
+			wantKeepDisabled:    7,
+		},
+		{
+			name: "first matching rule wins",
+			policy: &Policy{Rules: []Rule{
+				{Match: Match{Labels: map[string]string{"env": "prod"}}, KeepDisabled: 5},
+				{Match: Match{}, KeepDisabled: 9},
+			}},
+			secret:              store.Secret{Name: "s", Labels: map[string]string{"env": "prod"}},
+			defaultKeepDisabled: 1,
+			wantKeepEnabled:     1,
+			wantKeepDisabled:    5,
+		},
+		{
+			name:            "cleaner/skip label excludes the secret",
+			policy:          nil,
+			secret:          store.Secret{Name: "s", Labels: map[string]string{LabelSkip: "true"}},
+			wantSkip:        true,
+			wantKeepEnabled: 1,
+		},
+		{
+			name:                "cleaner/keep-versions label overrides keepDisabled",
+			policy:              nil,
+			secret:              store.Secret{Name: "s", Labels: map[string]string{LabelKeepVersions: "6"}},
+			defaultKeepDisabled: 2,
+			wantKeepEnabled:     1,
+			wantKeepDisabled:    6,
+		},
+		{
+			name: "label override takes precedence over a matched rule",
+			policy: &Policy{Rules: []Rule{
+				{Match: Match{}, KeepDisabled: 5},
+			}},
+			secret:              store.Secret{Name: "s", Labels: map[string]string{LabelKeepVersions: "9"}},
+			defaultKeepDisabled: 1,
+			wantKeepEnabled:     1,
+			wantKeepDisabled:    9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eff := tt.policy.Resolve(tt.secret, tt.defaultKeepDisabled)
+			if eff.Skip != tt.wantSkip {
+				t.Errorf("Skip = %v, want %v", eff.Skip, tt.wantSkip)
+			}
+			if eff.KeepEnabled != tt.wantKeepEnabled {
+				t.Errorf("KeepEnabled = %d, want %d", eff.KeepEnabled, tt.wantKeepEnabled)
+			}
+			if eff.KeepDisabled != tt.wantKeepDisabled {
+				t.Errorf("KeepDisabled = %d, want %d", eff.KeepDisabled, tt.wantKeepDisabled)
+			}
+			if eff.MinAge != tt.wantMinAge {
+				t.Errorf("MinAge = %v, want %v", eff.MinAge, tt.wantMinAge)
+			}
+		})
+	}
+}
+
+func TestResolvePinnedVersions(t *testing.T) {
+	p := &Policy{Rules: []Rule{
+		{Match: Match{}, PinnedVersions: []string{"v1", "v2"}},
+	}}
+	eff := p.Resolve(store.Secret{Name: "s"}, 1)
+	for _, v := range []string{"v1", "v2"} {
+		if !eff.Pinned[v] {
+			t.Errorf("Pinned[%q] = false, want true", v)
+		}
+	}
+	if eff.Pinned["v3"] {
+		t.Errorf("Pinned[\"v3\"] = true, want false")
+	}
+}
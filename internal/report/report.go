@@ -0,0 +1,108 @@
+// Package report builds the structured dry-run diff: every secret version
+// that a cleanup pass would disable or destroy, in both JSON and
+// human-readable Markdown form.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one version a dry-run pass would act on.
+type Entry struct {
+	Project    string        `json:"project"`
+	Secret     string        `json:"secret"`
+	Version    string        `json:"version"`
+	Action     string        `json:"action"` // "disable" or "destroy"
+	State      string        `json:"state"`  // the version's state before Action
+	CreateTime time.Time     `json:"create_time"`
+	Age        time.Duration `json:"age"`
+}
+
+// Report is the full dry-run diff for a cleanup pass.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New creates an empty Report stamped with generatedAt.
+func New(generatedAt time.Time) *Report {
+	return &Report{GeneratedAt: generatedAt}
+}
+
+// Add records one entry. Safe to call concurrently.
+func (r *Report) Add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+// Entries returns a copy of the recorded entries, sorted by project then
+// secret then version for stable output.
+func (r *Report) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Project != entries[j].Project {
+			return entries[i].Project < entries[j].Project
+		}
+		if entries[i].Secret != entries[j].Secret {
+			return entries[i].Secret < entries[j].Secret
+		}
+		return entries[i].Version < entries[j].Version
+	})
+	return entries
+}
+
+// JSON renders the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return r.JSONFrom(r.Entries())
+}
+
+// JSONFrom renders entries (e.g. a result already obtained from Entries, to
+// avoid re-sorting when also calling MarkdownFrom) as indented JSON.
+func (r *Report) JSONFrom(entries []Entry) ([]byte, error) {
+	return json.MarshalIndent(struct {
+		GeneratedAt time.Time `json:"generated_at"`
+		Entries     []Entry   `json:"entries"`
+	}{r.GeneratedAt, entries}, "", "  ")
+}
+
+// Markdown renders the report as a Markdown document, grouped by secret.
+func (r *Report) Markdown() string {
+	return r.MarkdownFrom(r.Entries())
+}
+
+// MarkdownFrom renders entries (e.g. a result already obtained from Entries,
+// to avoid re-sorting when also calling JSONFrom) as a Markdown document.
+func (r *Report) MarkdownFrom(entries []Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Secrets cleaner dry-run report\n\n")
+	fmt.Fprintf(&b, "Generated: %s\n\n", r.GeneratedAt.Format(time.RFC3339))
+	if len(entries) == 0 {
+		b.WriteString("No versions would be disabled or destroyed.\n")
+		return b.String()
+	}
+
+	var currentSecret string
+	for _, e := range entries {
+		secretKey := e.Project + "/" + e.Secret
+		if secretKey != currentSecret {
+			currentSecret = secretKey
+			fmt.Fprintf(&b, "## %s\n\n", secretKey)
+			b.WriteString("| Version | Action | State | Created | Age |\n")
+			b.WriteString("|---|---|---|---|---|\n")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			e.Version, e.Action, e.State, e.CreateTime.Format(time.RFC3339), e.Age.Round(time.Second))
+	}
+	return b.String()
+}
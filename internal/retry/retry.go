@@ -0,0 +1,62 @@
+// Package retry provides a small exponential-backoff-with-jitter retry
+// helper for transient errors from secrets backends.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Config controls the backoff schedule.
+type Config struct {
+	// MaxAttempts is the maximum number of calls to fn, including the first.
+	MaxAttempts int
+	// Initial is the delay before the first retry.
+	Initial time.Duration
+	// Max caps the delay between retries.
+	Max time.Duration
+	// Multiplier grows the delay after each retry.
+	Multiplier float64
+}
+
+// DefaultConfig is a sensible backoff schedule for Secret Manager-style
+// transient errors: 500ms, 1s, 2s, 4s (capped at 30s), 5 attempts total.
+var DefaultConfig = Config{
+	MaxAttempts: 5,
+	Initial:     500 * time.Millisecond,
+	Max:         30 * time.Second,
+	Multiplier:  2,
+}
+
+// Do calls fn, retrying with exponential backoff and jitter while
+// isRetryable(err) is true, up to cfg.MaxAttempts, or until ctx is done. It
+// returns the last error seen.
+func Do(ctx context.Context, cfg Config, isRetryable func(error) bool, fn func() error) error {
+	delay := cfg.Initial
+	var err error
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if attempt == cfg.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		jittered := time.Duration(float64(delay) * (0.5 + rand.Float64()))
+		timer := time.NewTimer(jittered)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		delay = time.Duration(float64(delay) * cfg.Multiplier)
+		if delay > cfg.Max {
+			delay = cfg.Max
+		}
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+var errPermanent = errors.New("permanent")
+
+func fastConfig(maxAttempts int) Config {
+	return Config{
+		MaxAttempts: maxAttempts,
+		Initial:     time.Millisecond,
+		Max:         5 * time.Millisecond,
+		Multiplier:  2,
+	}
+}
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestDoSucceedsWithoutRetrying(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastConfig(5), isTransient, func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDoRetriesTransientErrorsUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastConfig(5), isTransient, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDoStopsOnNonRetryableError(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastConfig(5), isTransient, func() error {
+		calls++
+		return errPermanent
+	})
+	if !errors.Is(err, errPermanent) {
+		t.Fatalf("err = %v, want errPermanent", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDoGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastConfig(3), isTransient, func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("err = %v, want errTransient", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDoReturnsCtxErrOnCancelDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, fastConfig(5), isTransient, func() error {
+		calls++
+		if calls == 1 {
+			cancel()
+		}
+		return errTransient
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,177 @@
+// Package aws implements store.SecretStore against AWS Secrets Manager.
+//
+// AWS Secrets Manager has no native enabled/disabled flag on a version: a
+// version is "enabled" here if it still carries the AWSCURRENT or
+// AWSPREVIOUS staging label, and "disabled" if it carries neither of them.
+// Disabling a version removes those two staging labels; AWS Secrets Manager has no
+// API to destroy a single version outright, so DestroyVersion returns an
+// error rather than silently doing nothing.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+// ErrDestroyUnsupported is returned by DestroyVersion: AWS Secrets Manager
+// does not support destroying a single secret version. It wraps
+// store.ErrDestroyUnsupported so callers can check for it with errors.Is
+// without importing this package.
+var ErrDestroyUnsupported = fmt.Errorf("aws: destroying a single secret version is not supported: %w", store.ErrDestroyUnsupported)
+
+// AWS Secrets Manager staging labels are plain strings, not SDK-defined
+// enum values.
+const (
+	stageAWSCURRENT  = "AWSCURRENT"
+	stageAWSPREVIOUS = "AWSPREVIOUS"
+)
+
+// Store implements store.SecretStore against AWS Secrets Manager. scope is
+// ignored; the backend's region comes from the AWS SDK's default
+// credential/config chain.
+type Store struct {
+	client *secretsmanager.Client
+}
+
+// New creates a Store using the default AWS config and credential chain.
+func New(ctx context.Context) (*Store, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &Store{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+// ListSecrets returns every secret in the account/region.
+func (s *Store) ListSecrets(ctx context.Context, scope string) ([]store.Secret, error) {
+	var secrets []store.Secret
+	var nextToken *string
+	for {
+		resp, err := s.client.ListSecrets(ctx, &secretsmanager.ListSecretsInput{NextToken: nextToken})
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range resp.SecretList {
+			labels := make(map[string]string, len(entry.Tags))
+			for _, tag := range entry.Tags {
+				labels[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			secrets = append(secrets, store.Secret{Name: aws.ToString(entry.ARN), Labels: labels})
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+	return secrets, nil
+}
+
+// ListVersions returns secret's versions matching filter.
+func (s *Store) ListVersions(ctx context.Context, secret store.Secret, filter store.VersionState) ([]store.Version, error) {
+	var versions []store.Version
+	var nextToken *string
+	for {
+		resp, err := s.client.ListSecretVersionIds(ctx, &secretsmanager.ListSecretVersionIdsInput{
+			SecretId:  aws.String(secret.Name),
+			NextToken: nextToken,
+			// Versions with no staging labels are only listed when
+			// deprecated versions are included.
+			IncludeDeprecated: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Versions {
+			state := versionState(v.VersionStages)
+			if filter != store.VersionStateUnspecified && state != filter {
+				continue
+			}
+			versions = append(versions, store.Version{
+				Name:       aws.ToString(v.VersionId),
+				Secret:     secret,
+				State:      state,
+				CreateTime: aws.ToTime(v.CreatedDate),
+			})
+		}
+		if resp.NextToken == nil {
+			break
+		}
+		nextToken = resp.NextToken
+	}
+	// ListSecretVersionIds does not guarantee ordering; the engine depends
+	// on newest-first ordering to decide which versions to keep.
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreateTime.After(versions[j].CreateTime)
+	})
+	return versions, nil
+}
+
+func versionState(stages []string) store.VersionState {
+	for _, stage := range stages {
+		if stage == stageAWSCURRENT || stage == stageAWSPREVIOUS {
+			return store.VersionStateEnabled
+		}
+	}
+	return store.VersionStateDisabled
+}
+
+// DisableVersion removes the AWSCURRENT and AWSPREVIOUS staging labels v
+// actually carries, so it no longer counts as enabled; other labels stay. A version may carry AWSCURRENT, AWSPREVIOUS, or
+// both; removing a stage it doesn't have errors, so the stages present are
+// looked up first rather than assumed.
+func (s *Store) DisableVersion(ctx context.Context, v store.Version) error {
+	stages, err := s.versionStages(ctx, v)
+	if err != nil {
+		return fmt.Errorf("failed to look up stages for version %s: %w", v.Name, err)
+	}
+	for _, stage := range stages {
+		if stage != stageAWSCURRENT && stage != stageAWSPREVIOUS {
+			continue
+		}
+		_, err := s.client.UpdateSecretVersionStage(ctx, &secretsmanager.UpdateSecretVersionStageInput{
+			SecretId:            aws.String(v.Secret.Name),
+			VersionStage:        aws.String(stage),
+			RemoveFromVersionId: aws.String(v.Name),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionStages returns the staging labels v currently carries.
+func (s *Store) versionStages(ctx context.Context, v store.Version) ([]string, error) {
+	var nextToken *string
+	for {
+		resp, err := s.client.ListSecretVersionIds(ctx, &secretsmanager.ListSecretVersionIdsInput{
+			SecretId:  aws.String(v.Secret.Name),
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range resp.Versions {
+			if aws.ToString(candidate.VersionId) == v.Name {
+				return candidate.VersionStages, nil
+			}
+		}
+		if resp.NextToken == nil {
+			return nil, nil
+		}
+		nextToken = resp.NextToken
+	}
+}
+
+// DestroyVersion always fails: AWS Secrets Manager does not support
+// destroying a single version.
+func (s *Store) DestroyVersion(ctx context.Context, v store.Version) error {
+	return ErrDestroyUnsupported
+}
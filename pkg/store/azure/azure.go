@@ -0,0 +1,121 @@
+// Package azure implements store.SecretStore against Azure Key Vault.
+//
+// Key Vault versions carry a real enabled/disabled attribute, so
+// DisableVersion maps directly onto it. Key Vault has no way to destroy a
+// single version: only the whole secret (and, once soft-delete is on, only
+// via purge after deletion) can be removed, so DestroyVersion returns an
+// error rather than pretending to do something it can't.
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+// ErrDestroyUnsupported is returned by DestroyVersion: Key Vault does not
+// support destroying a single secret version. It wraps
+// store.ErrDestroyUnsupported so callers can check for it with errors.Is
+// without importing this package.
+var ErrDestroyUnsupported = fmt.Errorf("azure: destroying a single secret version is not supported: %w", store.ErrDestroyUnsupported)
+
+// Store implements store.SecretStore against the single Azure Key Vault
+// whose URL (e.g. "https://my-vault.vault.azure.net") is passed to New.
+// ListSecrets ignores scope.
+type Store struct {
+	client *azsecrets.Client
+}
+
+// New creates a Store for the given vault URL using the default Azure
+// credential chain.
+func New(vaultURL string) (*Store, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w", err)
+	}
+	client, err := azsecrets.NewClient(vaultURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Key Vault client: %w", err)
+	}
+	return &Store{client: client}, nil
+}
+
+// ListSecrets returns every secret in the vault.
+func (s *Store) ListSecrets(ctx context.Context, scope string) ([]store.Secret, error) {
+	var secrets []store.Secret
+	pager := s.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Value {
+			labels := make(map[string]string, len(item.Tags))
+			for k, v := range item.Tags {
+				if v != nil {
+					labels[k] = *v
+				}
+			}
+			secrets = append(secrets, store.Secret{Name: item.ID.Name(), Labels: labels})
+		}
+	}
+	return secrets, nil
+}
+
+// ListVersions returns secret's versions matching filter.
+func (s *Store) ListVersions(ctx context.Context, secret store.Secret, filter store.VersionState) ([]store.Version, error) {
+	var versions []store.Version
+	pager := s.client.NewListSecretPropertiesVersionsPager(secret.Name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Value {
+			state := store.VersionStateDisabled
+			if item.Attributes != nil && item.Attributes.Enabled != nil && *item.Attributes.Enabled {
+				state = store.VersionStateEnabled
+			}
+			if filter != store.VersionStateUnspecified && state != filter {
+				continue
+			}
+			var created time.Time
+			if item.Attributes != nil && item.Attributes.Created != nil {
+				created = *item.Attributes.Created
+			}
+			versions = append(versions, store.Version{
+				Name:       item.ID.Version(),
+				Secret:     secret,
+				State:      state,
+				CreateTime: created,
+			})
+		}
+	}
+	// The versions pager does not guarantee ordering; the engine depends on
+	// newest-first ordering to decide which versions to keep.
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreateTime.After(versions[j].CreateTime)
+	})
+	return versions, nil
+}
+
+// DisableVersion disables v.
+func (s *Store) DisableVersion(ctx context.Context, v store.Version) error {
+	enabled := false
+	_, err := s.client.UpdateSecretProperties(ctx, v.Secret.Name, v.Name, azsecrets.UpdateSecretPropertiesParameters{
+		SecretAttributes: &azsecrets.SecretAttributes{Enabled: &enabled},
+	}, nil)
+	return err
+}
+
+// DestroyVersion always fails: Azure Key Vault does not support destroying
+// a single secret version.
+func (s *Store) DestroyVersion(ctx context.Context, v store.Version) error {
+	return ErrDestroyUnsupported
+}
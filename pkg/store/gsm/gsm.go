@@ -0,0 +1,101 @@
+// Package gsm implements store.SecretStore against Google Secret Manager.
+package gsm
+
+import (
+	"context"
+	"fmt"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"google.golang.org/api/iterator"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+// Store implements store.SecretStore against Google Secret Manager. scope is
+// a bare GCP project ID.
+type Store struct {
+	client *secretmanager.Client
+}
+
+// New creates a Store backed by a real Secret Manager client.
+func New(ctx context.Context) (*Store, error) {
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Secret Manager client: %w", err)
+	}
+	return &Store{client: client}, nil
+}
+
+// Close releases the underlying Secret Manager client.
+func (s *Store) Close() error {
+	return s.client.Close()
+}
+
+// ListSecrets returns every secret in the given project, along with its
+// labels so retention policy can match on them.
+func (s *Store) ListSecrets(ctx context.Context, scope string) ([]store.Secret, error) {
+	req := &secretmanagerpb.ListSecretsRequest{Parent: "projects/" + scope}
+	var secrets []store.Secret
+	it := s.client.ListSecrets(ctx, req)
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, store.Secret{Name: resp.Name, Labels: resp.Labels})
+	}
+	return secrets, nil
+}
+
+// ListVersions returns secret's versions matching filter, newest first.
+func (s *Store) ListVersions(ctx context.Context, secret store.Secret, filter store.VersionState) ([]store.Version, error) {
+	req := &secretmanagerpb.ListSecretVersionsRequest{
+		Parent: secret.Name,
+		Filter: filterString(filter), // https://cloud.google.com/secret-manager/docs/filtering
+	}
+	var versions []store.Version
+	it := s.client.ListSecretVersions(ctx, req)
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, store.Version{
+			Name:       resp.Name,
+			Secret:     secret,
+			State:      filter,
+			CreateTime: resp.CreateTime.AsTime(),
+		})
+	}
+	return versions, nil
+}
+
+func filterString(state store.VersionState) string {
+	switch state {
+	case store.VersionStateEnabled:
+		return "state:ENABLED"
+	case store.VersionStateDisabled:
+		return "state:DISABLED"
+	default:
+		return ""
+	}
+}
+
+// DisableVersion disables v.
+func (s *Store) DisableVersion(ctx context.Context, v store.Version) error {
+	_, err := s.client.DisableSecretVersion(ctx, &secretmanagerpb.DisableSecretVersionRequest{Name: v.Name})
+	return err
+}
+
+// DestroyVersion permanently destroys v.
+func (s *Store) DestroyVersion(ctx context.Context, v store.Version) error {
+	_, err := s.client.DestroySecretVersion(ctx, &secretmanagerpb.DestroySecretVersionRequest{Name: v.Name})
+	return err
+}
@@ -0,0 +1,63 @@
+// Package store defines the SecretStore interface the cleaner engine uses
+// to enumerate and manage secret versions, independent of which secrets
+// backend they actually live in. See the gsm, aws, vault and azure
+// subpackages for the concrete implementations.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDestroyUnsupported is returned by DestroyVersion when the backend has
+// no way to destroy a single version (AWS Secrets Manager and Azure Key
+// Vault, for example, only support deleting an entire secret). Callers
+// should treat it as "nothing to do here", not as a per-secret failure.
+var ErrDestroyUnsupported = errors.New("store: destroying a single secret version is not supported by this backend")
+
+// VersionState is the lifecycle state of a secret version.
+type VersionState int
+
+const (
+	// VersionStateUnspecified matches versions in any state.
+	VersionStateUnspecified VersionState = iota
+	// VersionStateEnabled matches enabled versions; each backend package
+	// documents how it maps its own states onto this one.
+	VersionStateEnabled
+	// VersionStateDisabled matches disabled versions.
+	VersionStateDisabled
+)
+
+// Secret identifies a secret within a backend, along with any labels used by
+// retention policy overrides.
+type Secret struct {
+	Name   string
+	Labels map[string]string
+}
+
+// Version identifies a single version of a Secret.
+type Version struct {
+	Name       string
+	Secret     Secret
+	State      VersionState
+	CreateTime time.Time
+}
+
+// SecretStore is implemented by each supported secrets backend.
+type SecretStore interface {
+	// ListSecrets returns every secret within scope, a backend-specific
+	// parent such as a GCP project or a Vault mount path. The aws and azure
+	// stores ignore scope.
+	ListSecrets(ctx context.Context, scope string) ([]Secret, error)
+	// ListVersions returns the versions of secret whose state matches filter,
+	// sorted newest-first by CreateTime. The cleaner engine relies on this
+	// ordering to decide which versions to keep, so every implementation
+	// must return its result in this order.
+	ListVersions(ctx context.Context, secret Secret, filter VersionState) ([]Version, error)
+	// DisableVersion disables v.
+	DisableVersion(ctx context.Context, v Version) error
+	// DestroyVersion permanently destroys v. Implementations that have no way
+	// to destroy a single version return ErrDestroyUnsupported.
+	DestroyVersion(ctx context.Context, v Version) error
+}
@@ -0,0 +1,153 @@
+// Package vault implements store.SecretStore against a HashiCorp Vault KV
+// version 2 secrets engine.
+//
+// KV v2 has no enable/disable concept either: a version is "enabled" until
+// it is soft-deleted, and "disabled" once soft-deleted but not yet
+// destroyed. DisableVersion therefore maps to a soft delete
+// (DeleteVersions) and DestroyVersion to a hard delete (Destroy).
+package vault
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+
+	"github.com/SebastianPereiro/secrets-cleaner/pkg/store"
+)
+
+// Store implements store.SecretStore against one or more Vault KV v2
+// mounts. ListSecrets takes the mount path (e.g. "secret") as scope and
+// records it as part of each returned Secret.Name, so later ListVersions,
+// DisableVersion and DestroyVersion calls can recover which mount a secret
+// came from.
+type Store struct {
+	client *vaultapi.Client
+}
+
+// New creates a Store using the given Vault address and token.
+func New(addr, token string) (*Store, error) {
+	cfg := vaultapi.DefaultConfig()
+	cfg.Address = addr
+	client, err := vaultapi.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+	client.SetToken(token)
+	return &Store{client: client}, nil
+}
+
+// ListSecrets returns every secret under the mount path, recursing through
+// nested KV paths. The returned Secret.Name is prefixed with scope (the
+// mount) so that subsequent ListVersions/DisableVersion/DestroyVersion
+// calls can rebuild the correct <mount>/<operation>/<path> API path.
+func (s *Store) ListSecrets(ctx context.Context, scope string) ([]store.Secret, error) {
+	var secrets []store.Secret
+	var walk func(p string) error
+	walk = func(p string) error {
+		resp, err := s.client.Logical().ListWithContext(ctx, path.Join(scope, "metadata", p))
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return nil
+		}
+		keys, _ := resp.Data["keys"].([]interface{})
+		for _, k := range keys {
+			name, _ := k.(string)
+			if name == "" {
+				continue
+			}
+			child := path.Join(p, name)
+			if name[len(name)-1] == '/' {
+				if err := walk(child); err != nil {
+					return err
+				}
+				continue
+			}
+			secrets = append(secrets, store.Secret{Name: path.Join(scope, child)})
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// splitMount splits a Secret.Name produced by ListSecrets (mount-prefixed,
+// e.g. "secret/foo/bar") into its mount ("secret") and the path relative to
+// that mount ("foo/bar"). Vault's KV v2 API shape is
+// <mount>/<operation>/<path>, so the mount must be reinserted before the
+// operation segment, not appended after it.
+func splitMount(name string) (mount, subPath string) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// ListVersions returns secret's versions matching filter.
+func (s *Store) ListVersions(ctx context.Context, secret store.Secret, filter store.VersionState) ([]store.Version, error) {
+	mount, subPath := splitMount(secret.Name)
+	resp, err := s.client.Logical().ReadWithContext(ctx, path.Join(mount, "metadata", subPath))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	raw, _ := resp.Data["versions"].(map[string]interface{})
+	var versions []store.Version
+	for id, v := range raw {
+		info, _ := v.(map[string]interface{})
+		if destroyed, _ := info["destroyed"].(bool); destroyed {
+			continue
+		}
+		deletionTime, _ := info["deletion_time"].(string)
+		state := store.VersionStateEnabled
+		if deletionTime != "" {
+			state = store.VersionStateDisabled
+		}
+		if filter != store.VersionStateUnspecified && state != filter {
+			continue
+		}
+		createdStr, _ := info["created_time"].(string)
+		created, _ := time.Parse(time.RFC3339, createdStr)
+		versions = append(versions, store.Version{
+			Name:       id,
+			Secret:     secret,
+			State:      state,
+			CreateTime: created,
+		})
+	}
+	// raw is a map, so iteration order is random; the engine depends on
+	// newest-first ordering to decide which versions to keep.
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreateTime.After(versions[j].CreateTime)
+	})
+	return versions, nil
+}
+
+// DisableVersion soft-deletes v.
+func (s *Store) DisableVersion(ctx context.Context, v store.Version) error {
+	mount, subPath := splitMount(v.Secret.Name)
+	_, err := s.client.Logical().WriteWithContext(ctx, path.Join(mount, "delete", subPath), map[string]interface{}{
+		"versions": []string{v.Name},
+	})
+	return err
+}
+
+// DestroyVersion permanently destroys v.
+func (s *Store) DestroyVersion(ctx context.Context, v store.Version) error {
+	mount, subPath := splitMount(v.Secret.Name)
+	_, err := s.client.Logical().WriteWithContext(ctx, path.Join(mount, "destroy", subPath), map[string]interface{}{
+		"versions": []string{v.Name},
+	})
+	return err
+}